@@ -0,0 +1,171 @@
+package zTypes
+
+import "encoding/hex"
+
+// CompactBlock is the wallet-facing projection of a Block used by light
+// clients: it carries only what's needed to detect and spend shielded
+// notes, dropping fully-transparent transactions and the bulk of each
+// shielded transaction's proof data.
+type CompactBlock struct {
+	Height   int         `json:"height"`
+	Hash     string      `json:"hash"`
+	PrevHash string      `json:"prevHash"`
+	Time     int64       `json:"time"`
+	VTx      []CompactTx `json:"vtx"`
+}
+
+// CompactTx is the compact projection of a single shielded (or mixed)
+// transaction.
+type CompactTx struct {
+	Index   int                    `json:"index"`
+	Hash    string                 `json:"hash"`
+	Fee     float64                `json:"fee"`
+	Spends  []CompactSaplingSpend  `json:"spends"`
+	Outputs []CompactSaplingOutput `json:"outputs"`
+	Actions []CompactOrchardAction `json:"actions"`
+}
+
+// CompactSaplingSpend carries only the nullifier a wallet needs to detect
+// that one of its own notes was spent.
+type CompactSaplingSpend struct {
+	Nullifier string `json:"nf"`
+}
+
+// CompactSaplingOutput carries the note commitment, ephemeral key, and
+// the 52-byte ciphertext prefix a wallet trial-decrypts to detect an
+// incoming note, without needing the full 580-byte encCiphertext.
+type CompactSaplingOutput struct {
+	Cmu          string   `json:"cmu"`
+	EphemeralKey string   `json:"epk"`
+	Ciphertext   [52]byte `json:"ciphertext"`
+	Matched      bool     `json:"-"`
+}
+
+// CompactOrchardAction is the Orchard equivalent of a combined
+// CompactSaplingSpend/CompactSaplingOutput: one action folds a spend's
+// nullifier and an output's commitment/ciphertext together.
+type CompactOrchardAction struct {
+	Nullifier    string   `json:"nullifier"`
+	Cmx          string   `json:"cmx"`
+	EphemeralKey string   `json:"ephemeralKey"`
+	Ciphertext   [52]byte `json:"ciphertext"`
+	Matched      bool     `json:"-"`
+}
+
+// ViewingKey trial-decrypts a single shielded output's compact ciphertext
+// prefix to determine whether the note belongs to its wallet. zTypes
+// doesn't implement Sapling/Orchard note encryption itself; callers wire
+// in their own crypto library's trial decryption behind this interface.
+type ViewingKey interface {
+	TryDecrypt(ephemeralKey string, ciphertext [52]byte) bool
+}
+
+// ToCompact projects b into a CompactBlock, skipping every transaction
+// that carries no shielded data.
+func (b Block) ToCompact() CompactBlock {
+	cb := CompactBlock{
+		Height:   b.Height,
+		Hash:     b.Hash,
+		PrevHash: b.PreviousBlockHash,
+		Time:     b.Time,
+	}
+	for i, tx := range b.TX {
+		// CompactTx has no Sprout representation - lightwalletd doesn't
+		// serve JoinSplit data to light clients either - so a Sprout-only
+		// transaction is skipped here along with fully-transparent ones.
+		if !tx.ContainsSapling() && !tx.ContainsOrchard() {
+			continue
+		}
+		cb.VTx = append(cb.VTx, tx.toCompact(i))
+	}
+	return cb
+}
+
+func (t Transaction) toCompact(index int) CompactTx {
+	ct := CompactTx{Index: index, Hash: t.Txid, Fee: t.compactFee()}
+
+	for _, spend := range t.VShieldedSpend {
+		nf, _ := spend["nullifier"].(string)
+		ct.Spends = append(ct.Spends, CompactSaplingSpend{Nullifier: nf})
+	}
+
+	for _, out := range t.VShieldedOutput {
+		cmu, _ := out["cmu"].(string)
+		epk, _ := out["ephemeralKey"].(string)
+		enc, _ := out["encCiphertext"].(string)
+		ct.Outputs = append(ct.Outputs, CompactSaplingOutput{
+			Cmu:          cmu,
+			EphemeralKey: epk,
+			Ciphertext:   compactPrefix(enc),
+		})
+	}
+
+	if t.Orchard != nil {
+		for _, a := range t.Orchard.Actions {
+			ct.Actions = append(ct.Actions, CompactOrchardAction{
+				Nullifier:    a.Nullifier,
+				Cmx:          a.Cmx,
+				EphemeralKey: a.EphemeralKey,
+				Ciphertext:   compactPrefix(a.EncCiphertext),
+			})
+		}
+	}
+
+	return ct
+}
+
+// compactFee approximates the fee from shielded value balances and
+// Sprout pour amounts. It doesn't account for transparent inputs/outputs,
+// since computing those requires resolving each vin's previous output
+// value, which needs a UTXO lookup this package doesn't provide.
+func (t Transaction) compactFee() float64 {
+	fee := t.ValueBalance
+	if t.Orchard != nil {
+		fee += t.Orchard.ValueBalance
+	}
+	for _, js := range t.VJoinSplit {
+		fee += js.VPubNew - js.VPubOldld
+	}
+	return fee
+}
+
+// compactPrefix decodes a hex-encoded ciphertext and returns its first 52
+// bytes - enough for trial decryption without carrying the whole note.
+func compactPrefix(hexCiphertext string) [52]byte {
+	var prefix [52]byte
+	b, err := hex.DecodeString(hexCiphertext)
+	if err != nil {
+		return prefix
+	}
+	copy(prefix[:], b)
+	return prefix
+}
+
+// Filter trial-decrypts every output and action in cb against vks and
+// marks the ones that matched. It returns a new CompactBlock; cb is left
+// unmodified.
+func (cb CompactBlock) Filter(vks []ViewingKey) CompactBlock {
+	out := cb
+	out.VTx = make([]CompactTx, len(cb.VTx))
+	for i, tx := range cb.VTx {
+		tx.Outputs = append([]CompactSaplingOutput(nil), tx.Outputs...)
+		for j := range tx.Outputs {
+			tx.Outputs[j].Matched = anyKeyMatches(vks, tx.Outputs[j].EphemeralKey, tx.Outputs[j].Ciphertext)
+		}
+		tx.Actions = append([]CompactOrchardAction(nil), tx.Actions...)
+		for j := range tx.Actions {
+			tx.Actions[j].Matched = anyKeyMatches(vks, tx.Actions[j].EphemeralKey, tx.Actions[j].Ciphertext)
+		}
+		out.VTx[i] = tx
+	}
+	return out
+}
+
+func anyKeyMatches(vks []ViewingKey, epk string, ciphertext [52]byte) bool {
+	for _, vk := range vks {
+		if vk.TryDecrypt(epk, ciphertext) {
+			return true
+		}
+	}
+	return false
+}
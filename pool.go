@@ -0,0 +1,122 @@
+package zTypes
+
+import (
+	"fmt"
+)
+
+// AmountConstraint restricts which values an Amount may hold, mirroring
+// zcashd's CAmount validation: most balances may go negative (a spend),
+// but some (like a running chain supply) must never do so.
+type AmountConstraint int
+
+const (
+	// NegativeAllowed permits any int64 zatoshi value.
+	NegativeAllowed AmountConstraint = iota
+	// NonNegative rejects negative zatoshi values.
+	NonNegative
+)
+
+// Amount is a zatoshi quantity (1 ZEC = 1e8 zatoshis). Unlike the float64
+// ZEC amounts used elsewhere in this package for JSON compatibility with
+// zcashd's RPC output, Amount carries no rounding error, so sums over many
+// transactions can be reconciled exactly.
+type Amount struct {
+	zatoshis   int64
+	constraint AmountConstraint
+}
+
+// NewAmount builds an Amount, rejecting a negative value when constraint
+// is NonNegative.
+func NewAmount(zatoshis int64, constraint AmountConstraint) (Amount, error) {
+	if constraint == NonNegative && zatoshis < 0 {
+		return Amount{}, fmt.Errorf("zTypes: amount %d zatoshis violates NonNegative constraint", zatoshis)
+	}
+	return Amount{zatoshis: zatoshis, constraint: constraint}, nil
+}
+
+// Zatoshis returns the exact integer zatoshi value.
+func (a Amount) Zatoshis() int64 { return a.zatoshis }
+
+// ZEC returns the value converted to ZEC, for display or for
+// interoperating with this package's float64-based types.
+func (a Amount) ZEC() float64 { return zecAmount(a.zatoshis) }
+
+// Add returns a + b, enforcing a's constraint on the result.
+func (a Amount) Add(b Amount) (Amount, error) {
+	return NewAmount(a.zatoshis+b.zatoshis, a.constraint)
+}
+
+// PoolDeltas returns the per-pool value delta introduced by this block:
+// sprout, sapling, and orchard come straight from ValuePools, and
+// transparent is derived as whatever value the shielded pools didn't
+// absorb. The transparent figure omits this block's coinbase issuance,
+// since that isn't carried on Block.
+func (b Block) PoolDeltas() map[string]float64 {
+	deltas := make(map[string]float64, 4)
+	for _, pool := range b.ValuePools {
+		switch pool.ID {
+		case "sprout", "sapling", "orchard":
+			deltas[pool.ID] = pool.ValueDelta
+		}
+	}
+	deltas["transparent"] = -(deltas["sprout"] + deltas["sapling"] + deltas["orchard"])
+	return deltas
+}
+
+// CheckValueBalance verifies that this block's transactions account for
+// exactly the value movement recorded in its ValuePools: the Sprout pours'
+// vpub_new-vpub_old sums to -sproutDelta, the Sapling valueBalance sums to
+// -saplingDelta, and the Orchard valueBalanceOrchard sums to -orchardDelta.
+// Each side is converted to zatoshis and summed as int64 - rather than
+// accumulated in float64 - so per-tx rounding can't add up across a block
+// with many shielded transactions into a spurious mismatch.
+func (b Block) CheckValueBalance() error {
+	var sproutZat, saplingZat, orchardZat int64
+	for _, tx := range b.TX {
+		for _, js := range tx.VJoinSplit {
+			sproutZat += zatoshi(js.VPubNew) - zatoshi(js.VPubOldld)
+		}
+		saplingZat += zatoshi(tx.ValueBalance)
+		if tx.Orchard != nil {
+			orchardZat += zatoshi(tx.Orchard.ValueBalance)
+		}
+	}
+
+	deltas := b.PoolDeltas()
+	if want := -zatoshi(deltas["sprout"]); sproutZat != want {
+		return fmt.Errorf("zTypes: block %d sprout value balance mismatch: vpub sum %d zat, pool delta %d zat", b.Height, sproutZat, want)
+	}
+	if want := -zatoshi(deltas["sapling"]); saplingZat != want {
+		return fmt.Errorf("zTypes: block %d sapling value balance mismatch: valueBalance sum %d zat, pool delta %d zat", b.Height, saplingZat, want)
+	}
+	if want := -zatoshi(deltas["orchard"]); orchardZat != want {
+		return fmt.Errorf("zTypes: block %d orchard value balance mismatch: valueBalanceOrchard sum %d zat, pool delta %d zat", b.Height, orchardZat, want)
+	}
+	return nil
+}
+
+// ChainSupply folds a stream of blocks into cumulative, exact per-pool
+// supply totals.
+type ChainSupply struct {
+	Height int
+	Totals map[string]Amount
+}
+
+// NewChainSupply returns an empty ChainSupply.
+func NewChainSupply() *ChainSupply {
+	return &ChainSupply{Totals: make(map[string]Amount)}
+}
+
+// Add folds b's pool deltas into the running totals and advances Height.
+// Blocks must be added in height order; Add doesn't itself verify that.
+func (s *ChainSupply) Add(b Block) error {
+	for id, delta := range b.PoolDeltas() {
+		updated, err := s.Totals[id].Add(Amount{zatoshis: zatoshi(delta)})
+		if err != nil {
+			return fmt.Errorf("zTypes: block %d: %w", b.Height, err)
+		}
+		s.Totals[id] = updated
+	}
+	s.Height = b.Height
+	return nil
+}
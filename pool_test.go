@@ -0,0 +1,68 @@
+package zTypes
+
+import "testing"
+
+func valuePools(sprout, sapling, orchard float64) []ValuePool {
+	return []ValuePool{
+		{ID: "sprout", ValueDelta: sprout},
+		{ID: "sapling", ValueDelta: sapling},
+		{ID: "orchard", ValueDelta: orchard},
+	}
+}
+
+func TestCheckValueBalanceAccepts(t *testing.T) {
+	// 10,000 shielded transactions each moving 0.00000001 ZEC (1 zatoshi)
+	// into the sapling pool: a block whose pool delta was reconciled with a
+	// fixed float64 tolerance would have accumulated enough per-tx rounding
+	// error over that many additions to report a spurious mismatch.
+	const n = 10000
+	b := Block{Height: 1}
+	for i := 0; i < n; i++ {
+		b.TX = append(b.TX, Transaction{ValueBalance: -0.00000001})
+	}
+	b.ValuePools = valuePools(0, 0.0001, 0) // n * 1 zat = 10000 zat = 0.0001 ZEC
+
+	if err := b.CheckValueBalance(); err != nil {
+		t.Fatalf("CheckValueBalance: %v", err)
+	}
+}
+
+func TestCheckValueBalanceDetectsSaplingMismatch(t *testing.T) {
+	b := Block{
+		Height:     2,
+		TX:         []Transaction{{ValueBalance: -1.5}},
+		ValuePools: valuePools(0, 1.0, 0), // pool only recorded 1.0 ZEC in, but the tx moved 1.5
+	}
+
+	if err := b.CheckValueBalance(); err == nil {
+		t.Fatal("expected a sapling value balance mismatch, got nil")
+	}
+}
+
+func TestCheckValueBalanceDetectsSproutMismatch(t *testing.T) {
+	b := Block{
+		Height: 3,
+		TX: []Transaction{
+			{VJoinSplit: []VJoinSplitTX{{VPubNew: 2, VPubOldld: 0}}},
+		},
+		ValuePools: valuePools(-1, 0, 0), // pool says 1 ZEC left sprout, but 2 ZEC actually did
+	}
+
+	if err := b.CheckValueBalance(); err == nil {
+		t.Fatal("expected a sprout value balance mismatch, got nil")
+	}
+}
+
+func TestCheckValueBalanceAcceptsOrchard(t *testing.T) {
+	b := Block{
+		Height: 4,
+		TX: []Transaction{
+			{Orchard: &OrchardBundle{ValueBalance: -3}},
+		},
+		ValuePools: valuePools(0, 0, 3),
+	}
+
+	if err := b.CheckValueBalance(); err != nil {
+		t.Fatalf("CheckValueBalance: %v", err)
+	}
+}
@@ -0,0 +1,183 @@
+// Package rpc provides a pluggable zcashd JSON-RPC client and a streaming
+// block ingester built on top of it.
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	zTypes "github.com/zcash-hackworks/zTypes"
+)
+
+// Client is the subset of zcashd's JSON-RPC surface that Streamer and its
+// callers need. Implementations may talk to zcashd directly, a caching
+// proxy, or a test fixture.
+type Client interface {
+	GetBlockchainInfo(ctx context.Context) (zTypes.GetBlockchainInfo, error)
+	GetBlock(ctx context.Context, heightOrHash string, verbosity int) (zTypes.Block, error)
+	GetRawTransaction(ctx context.Context, txid string, verbose bool) (zTypes.Transaction, error)
+	GetChainTips(ctx context.Context) ([]ChainTip, error)
+}
+
+// ChainTip is one entry of zcashd's `getchaintips` response.
+type ChainTip struct {
+	Height    int    `json:"height"`
+	Hash      string `json:"hash"`
+	BranchLen int    `json:"branchlen"`
+	Status    string `json:"status"`
+}
+
+// HTTPClient implements Client over zcashd's HTTP JSON-RPC endpoint.
+type HTTPClient struct {
+	endpoint   string
+	httpClient *http.Client
+	user, pass string
+	err        error
+}
+
+// Option configures an HTTPClient.
+type Option func(*HTTPClient)
+
+// WithHTTPClient overrides the http.Client used for requests, e.g. to set
+// a timeout or a custom transport.
+func WithHTTPClient(c *http.Client) Option {
+	return func(h *HTTPClient) { h.httpClient = c }
+}
+
+// WithBasicAuth authenticates using zcashd's `rpcuser`/`rpcpassword`.
+func WithBasicAuth(user, pass string) Option {
+	return func(h *HTTPClient) { h.user, h.pass = user, pass }
+}
+
+// WithCookieFile authenticates using zcashd's auto-generated `.cookie`
+// file (the default when rpcuser/rpcpassword aren't set in zcash.conf).
+// The file is read once, at construction time.
+func WithCookieFile(path string) Option {
+	return func(h *HTTPClient) {
+		user, pass, err := readCookie(path)
+		if err != nil {
+			h.err = err
+			return
+		}
+		h.user, h.pass = user, pass
+	}
+}
+
+func readCookie(path string) (user, pass string, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	parts := strings.SplitN(strings.TrimSpace(string(data)), ":", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("rpc: malformed cookie file %s", path)
+	}
+	return parts[0], parts[1], nil
+}
+
+// NewHTTPClient returns a Client that talks to the zcashd JSON-RPC
+// endpoint whose URL is endpoint, authenticated via WithBasicAuth or WithCookieFile.
+func NewHTTPClient(endpoint string, opts ...Option) (*HTTPClient, error) {
+	c := &HTTPClient{endpoint: endpoint, httpClient: http.DefaultClient}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c, nil
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      string        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("rpc: zcashd returned error %d: %s", e.Code, e.Message)
+}
+
+func (c *HTTPClient) call(ctx context.Context, method string, params []interface{}, result interface{}) error {
+	body, err := json.Marshal(rpcRequest{JSONRPC: "1.0", ID: "zTypes", Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.pass)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var rpcResp rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("rpc: decoding %s response: %w", method, err)
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// GetBlockchainInfo calls `getblockchaininfo`.
+func (c *HTTPClient) GetBlockchainInfo(ctx context.Context) (zTypes.GetBlockchainInfo, error) {
+	var info zTypes.GetBlockchainInfo
+	err := c.call(ctx, "getblockchaininfo", nil, &info)
+	return info, err
+}
+
+// GetBlock calls `getblock` for the given height or hash at the given
+// verbosity (0 for hex, 1 for a decoded Block with transaction IDs, 2 for
+// a decoded Block with full transactions).
+func (c *HTTPClient) GetBlock(ctx context.Context, heightOrHash string, verbosity int) (zTypes.Block, error) {
+	var block zTypes.Block
+	err := c.call(ctx, "getblock", []interface{}{heightOrHash, verbosity}, &block)
+	return block, err
+}
+
+// GetRawTransaction calls `getrawtransaction` for txid.
+func (c *HTTPClient) GetRawTransaction(ctx context.Context, txid string, verbose bool) (zTypes.Transaction, error) {
+	verbosity := 0
+	if verbose {
+		verbosity = 1
+	}
+	var tx zTypes.Transaction
+	err := c.call(ctx, "getrawtransaction", []interface{}{txid, verbosity}, &tx)
+	return tx, err
+}
+
+// GetChainTips calls `getchaintips`.
+func (c *HTTPClient) GetChainTips(ctx context.Context) ([]ChainTip, error) {
+	var tips []ChainTip
+	err := c.call(ctx, "getchaintips", nil, &tips)
+	return tips, err
+}
@@ -0,0 +1,195 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	zTypes "github.com/zcash-hackworks/zTypes"
+)
+
+// StreamResult is a single value emitted by Streamer.Stream: either a
+// decoded Block or the error that stopped the stream.
+type StreamResult struct {
+	Block zTypes.Block
+	Err   error
+}
+
+// Streamer walks a height range against a Client, fetching blocks
+// concurrently across a bounded worker pool while still emitting them on
+// its output channel in height order.
+type Streamer struct {
+	Client  Client
+	Workers int
+}
+
+// NewStreamer returns a Streamer that fetches up to workers blocks
+// concurrently. workers < 1 is treated as 1.
+func NewStreamer(client Client, workers int) *Streamer {
+	if workers < 1 {
+		workers = 1
+	}
+	return &Streamer{Client: client, Workers: workers}
+}
+
+// job is a dispatch to a worker. gen is the generation it was dispatched
+// under; a result carrying a stale gen is discarded rather than emitted,
+// which is how a reorg invalidates already in-flight fetches.
+type job struct {
+	height int
+	gen    int
+}
+
+type fetchedBlock struct {
+	height int
+	gen    int
+	block  zTypes.Block
+	err    error
+}
+
+// Stream fetches blocks [fromHeight, toHeight] with full transaction
+// bodies (getblock verbosity 2) and emits them on the returned channel in
+// height order. It stops and closes the channel when ctx is canceled,
+// when toHeight is reached, or on the first fetch error.
+//
+// Before emitting a block it checks that block.PreviousBlockHash matches
+// the hash of the block it last emitted; on a mismatch it assumes the
+// chain reorged under it, and invalidates and re-dispatches every height
+// at or above the rewind point - both those still buffered and those
+// already in flight with a worker - before continuing forward.
+func (s *Streamer) Stream(ctx context.Context, fromHeight, toHeight int) (<-chan StreamResult, error) {
+	if toHeight < fromHeight {
+		return nil, fmt.Errorf("rpc: toHeight %d precedes fromHeight %d", toHeight, fromHeight)
+	}
+
+	jobs := make(chan job)
+	results := make(chan fetchedBlock, s.Workers)
+	out := make(chan StreamResult)
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				block, err := s.Client.GetBlock(ctx, strconv.Itoa(j.height), 2)
+				select {
+				case results <- fetchedBlock{height: j.height, gen: j.gen, block: block, err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(out)
+		s.sequence(ctx, fromHeight, toHeight, jobs, results, out)
+		close(jobs)
+		wg.Wait()
+	}()
+
+	return out, nil
+}
+
+// sequence owns dispatch, reordering, and reorg recovery. It keeps at most
+// Workers heights in flight ahead of the next height to emit, buffers
+// completed fetches in pending until they can be emitted in order, and on
+// a detected reorg bumps the generation counter and rewinds so every
+// height at or above the rewind point is invalidated (dropped from
+// pending, and any matching in-flight result discarded on arrival by its
+// stale generation) and re-dispatched. The re-dispatched boundary block is
+// re-validated against the last known-good hash below it, so a reorg
+// deeper than one block keeps walking back - rewind, re-fetch, check,
+// rewind again - until it finds the actual fork point or runs out of
+// history at fromHeight.
+func (s *Streamer) sequence(ctx context.Context, fromHeight, toHeight int, jobs chan<- job, results <-chan fetchedBlock, out chan<- StreamResult) {
+	pending := make(map[int]fetchedBlock)
+	emittedHash := make(map[int]string)
+	next := fromHeight
+	dispatched := fromHeight
+	gen := 0
+	var lastHash string
+
+	dispatchUpTo := func() bool {
+		for dispatched <= toHeight && dispatched < next+s.Workers {
+			select {
+			case jobs <- job{height: dispatched, gen: gen}:
+				dispatched++
+			case <-ctx.Done():
+				return false
+			}
+		}
+		return true
+	}
+
+	if !dispatchUpTo() {
+		return
+	}
+
+	for next <= toHeight {
+		f, ok := pending[next]
+		if !ok {
+			select {
+			case r, ok := <-results:
+				if !ok {
+					return
+				}
+				if r.gen != gen || r.height < next {
+					// Stale result from before the last reorg; its height
+					// has already been (or is about to be) re-dispatched
+					// under the current generation.
+					continue
+				}
+				pending[r.height] = r
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+		delete(pending, next)
+
+		if f.err != nil {
+			select {
+			case out <- StreamResult{Err: f.err}:
+			case <-ctx.Done():
+			}
+			return
+		}
+
+		if lastHash != "" && f.block.PreviousBlockHash != lastHash {
+			rewindTo := next - 1
+			gen++
+			for h := range pending {
+				if h >= rewindTo {
+					delete(pending, h)
+				}
+			}
+			next = rewindTo
+			dispatched = rewindTo
+			// Re-validate the re-fetched boundary block against the last
+			// known-good hash below it, rather than trusting it outright -
+			// if the reorg goes deeper than one block this will mismatch
+			// again and rewind further back.
+			lastHash = emittedHash[rewindTo-1]
+			if !dispatchUpTo() {
+				return
+			}
+			continue
+		}
+
+		select {
+		case out <- StreamResult{Block: f.block}:
+		case <-ctx.Done():
+			return
+		}
+		emittedHash[next] = f.block.Hash
+		lastHash = f.block.Hash
+		next++
+
+		if !dispatchUpTo() {
+			return
+		}
+	}
+}
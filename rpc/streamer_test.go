@@ -0,0 +1,211 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+
+	zTypes "github.com/zcash-hackworks/zTypes"
+)
+
+// fakeClient serves blocks from an in-memory chain keyed by height, and
+// records the verbosity each GetBlock call was made with. Streamer fetches
+// concurrently across its worker pool, so every access is guarded by mu.
+type fakeClient struct {
+	mu sync.Mutex
+
+	chain     map[int]zTypes.Block
+	verbosity []int
+	served    map[int]int
+
+	// reorgAfter maps a height to the number of times it must be served
+	// from chain before switching to reorged - simulating the chain
+	// reorganizing under the streamer mid-stream. A height absent from
+	// reorgAfter never switches.
+	reorgAfter map[int]int
+	reorged    map[int]zTypes.Block
+}
+
+func (f *fakeClient) GetBlockchainInfo(ctx context.Context) (zTypes.GetBlockchainInfo, error) {
+	return zTypes.GetBlockchainInfo{}, nil
+}
+
+func (f *fakeClient) GetRawTransaction(ctx context.Context, txid string, verbose bool) (zTypes.Transaction, error) {
+	return zTypes.Transaction{}, nil
+}
+
+func (f *fakeClient) GetChainTips(ctx context.Context) ([]ChainTip, error) {
+	return nil, nil
+}
+
+func (f *fakeClient) GetBlock(ctx context.Context, heightOrHash string, verbosity int) (zTypes.Block, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.verbosity = append(f.verbosity, verbosity)
+
+	height, err := strconv.Atoi(heightOrHash)
+	if err != nil {
+		return zTypes.Block{}, fmt.Errorf("fakeClient only serves by height, got %q", heightOrHash)
+	}
+
+	if f.served == nil {
+		f.served = make(map[int]int)
+	}
+	f.served[height]++
+
+	if threshold, ok := f.reorgAfter[height]; ok && f.served[height] > threshold {
+		if b, ok := f.reorged[height]; ok {
+			return b, nil
+		}
+	}
+
+	b, ok := f.chain[height]
+	if !ok {
+		return zTypes.Block{}, fmt.Errorf("fakeClient: no block at height %d", height)
+	}
+	return b, nil
+}
+
+func chainBlock(height int, hash, prevHash string) zTypes.Block {
+	return zTypes.Block{Height: height, Hash: hash, PreviousBlockHash: prevHash}
+}
+
+func TestStreamUsesVerbosity2(t *testing.T) {
+	client := &fakeClient{chain: map[int]zTypes.Block{
+		1: chainBlock(1, "h1", "h0"),
+		2: chainBlock(2, "h2", "h1"),
+	}}
+	s := NewStreamer(client, 2)
+
+	out, err := s.Stream(context.Background(), 1, 2)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+	for range out {
+	}
+
+	for _, v := range client.verbosity {
+		if v != 2 {
+			t.Fatalf("GetBlock called with verbosity %d, want 2 (verbosity 1 omits full transaction bodies)", v)
+		}
+	}
+}
+
+// TestStreamRecoversFromReorg simulates a chain that reorgs at height 3:
+// the first time height 3 is fetched, it arrives with a PreviousBlockHash
+// that doesn't match the height-2 block already emitted. The streamer must
+// rewind, re-fetch height 2 under the new chain, and emit a final sequence
+// that chains together consistently end to end.
+func TestStreamRecoversFromReorg(t *testing.T) {
+	client := &fakeClient{
+		chain: map[int]zTypes.Block{
+			1: chainBlock(1, "h1", "h0"),
+			2: chainBlock(2, "h2-old", "h1"),
+			3: chainBlock(3, "h3", "h2-new"),
+		},
+		reorgAfter: map[int]int{2: 1},
+		reorged: map[int]zTypes.Block{
+			2: chainBlock(2, "h2-new", "h1"),
+		},
+	}
+	s := NewStreamer(client, 2)
+
+	out, err := s.Stream(context.Background(), 1, 3)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+
+	var emitted []zTypes.Block
+	for r := range out {
+		if r.Err != nil {
+			t.Fatalf("unexpected stream error: %v", r.Err)
+		}
+		emitted = append(emitted, r.Block)
+	}
+
+	// Keep only each height's last emission - the final, reconciled view.
+	final := make(map[int]zTypes.Block)
+	for _, b := range emitted {
+		final[b.Height] = b
+	}
+
+	var heights []int
+	for h := range final {
+		heights = append(heights, h)
+	}
+	sort.Ints(heights)
+	if len(heights) != 3 {
+		t.Fatalf("expected final blocks at heights 1-3, got %v", heights)
+	}
+
+	if final[2].Hash != "h2-new" {
+		t.Fatalf("height 2 should have settled on the reorged block, got hash %q", final[2].Hash)
+	}
+	if final[2].PreviousBlockHash != final[1].Hash {
+		t.Fatalf("height 2 doesn't chain to height 1: got prevHash %q, height 1 hash %q", final[2].PreviousBlockHash, final[1].Hash)
+	}
+	if final[3].PreviousBlockHash != final[2].Hash {
+		t.Fatalf("height 3 doesn't chain to height 2: got prevHash %q, height 2 hash %q", final[3].PreviousBlockHash, final[2].Hash)
+	}
+}
+
+// TestStreamRecoversFromDeepReorg simulates a reorg two blocks deep: height
+// 4 arrives pointing at a height-3 hash the streamer never emitted, and the
+// naive one-block rewind (re-fetch height 3 alone) still doesn't chain to
+// the already-emitted height 2 - the streamer must keep walking back until
+// it reaches the real fork point (height 1) and re-validate every height
+// above it.
+func TestStreamRecoversFromDeepReorg(t *testing.T) {
+	client := &fakeClient{
+		chain: map[int]zTypes.Block{
+			1: chainBlock(1, "h1", "h0"),
+			2: chainBlock(2, "h2-old", "h1"),
+			3: chainBlock(3, "h3-old", "h2-old"),
+			// height 4's only version already reflects the new chain - as
+			// if the node reorged out from under the streamer between
+			// fetching height 3 and height 4.
+			4: chainBlock(4, "h4-new", "h3-new"),
+		},
+		reorgAfter: map[int]int{2: 1, 3: 1},
+		reorged: map[int]zTypes.Block{
+			2: chainBlock(2, "h2-new", "h1"),
+			3: chainBlock(3, "h3-new", "h2-new"),
+		},
+	}
+	s := NewStreamer(client, 1)
+
+	out, err := s.Stream(context.Background(), 1, 4)
+	if err != nil {
+		t.Fatalf("Stream: %v", err)
+	}
+
+	var emitted []zTypes.Block
+	for r := range out {
+		if r.Err != nil {
+			t.Fatalf("unexpected stream error: %v", r.Err)
+		}
+		emitted = append(emitted, r.Block)
+	}
+
+	final := make(map[int]zTypes.Block)
+	for _, b := range emitted {
+		final[b.Height] = b
+	}
+
+	if len(final) != 4 {
+		t.Fatalf("expected final blocks at heights 1-4, got %d", len(final))
+	}
+	for h := 2; h <= 4; h++ {
+		if final[h].PreviousBlockHash != final[h-1].Hash {
+			t.Fatalf("height %d doesn't chain to height %d: got prevHash %q, height %d hash %q",
+				h, h-1, final[h].PreviousBlockHash, h-1, final[h-1].Hash)
+		}
+	}
+	if final[2].Hash != "h2-new" || final[3].Hash != "h3-new" {
+		t.Fatalf("expected heights 2 and 3 to settle on the reorged chain, got %q and %q", final[2].Hash, final[3].Hash)
+	}
+}
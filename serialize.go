@@ -0,0 +1,366 @@
+package zTypes
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// maxBlockBytes mirrors zcashd's MAX_BLOCK_SIZE. It's the upper bound used
+// by TrustedPreallocate: no serialized block can exceed it, so a
+// CompactSize count that would need more than maxBlockBytes to encode at
+// minItemSize bytes/item is necessarily bogus, and we refuse to allocate
+// for it.
+const maxBlockBytes = 2000000
+
+var errTooManyItems = errors.New("zTypes: CompactSize count exceeds TrustedPreallocate bound for maxBlockBytes")
+
+// trustedPreallocateBound returns the largest item count that could still
+// fit in a maxBlockBytes block given minItemSize bytes per item.
+func trustedPreallocateBound(minItemSize int) uint64 {
+	return uint64(maxBlockBytes / minItemSize)
+}
+
+// writeCompactSize writes n using the Bitcoin/Zcash CompactSize varint
+// encoding used to length-prefix every vector in the wire format.
+func writeCompactSize(w io.Writer, n uint64) error {
+	switch {
+	case n < 0xfd:
+		return binary.Write(w, binary.LittleEndian, uint8(n))
+	case n <= 0xffff:
+		if err := binary.Write(w, binary.LittleEndian, uint8(0xfd)); err != nil {
+			return err
+		}
+		return binary.Write(w, binary.LittleEndian, uint16(n))
+	case n <= 0xffffffff:
+		if err := binary.Write(w, binary.LittleEndian, uint8(0xfe)); err != nil {
+			return err
+		}
+		return binary.Write(w, binary.LittleEndian, uint32(n))
+	default:
+		if err := binary.Write(w, binary.LittleEndian, uint8(0xff)); err != nil {
+			return err
+		}
+		return binary.Write(w, binary.LittleEndian, n)
+	}
+}
+
+// readCompactSize reads a CompactSize varint and enforces the
+// TrustedPreallocate bound for minItemSize before the caller allocates a
+// slice of the returned length, so an untrusted peer can't drive an OOM
+// by claiming an absurd vector length.
+func readCompactSize(r io.Reader, minItemSize int) (uint64, error) {
+	var prefix uint8
+	if err := binary.Read(r, binary.LittleEndian, &prefix); err != nil {
+		return 0, err
+	}
+
+	var n uint64
+	switch prefix {
+	case 0xfd:
+		var v uint16
+		if err := binary.Read(r, binary.LittleEndian, &v); err != nil {
+			return 0, err
+		}
+		n = uint64(v)
+	case 0xfe:
+		var v uint32
+		if err := binary.Read(r, binary.LittleEndian, &v); err != nil {
+			return 0, err
+		}
+		n = uint64(v)
+	case 0xff:
+		if err := binary.Read(r, binary.LittleEndian, &n); err != nil {
+			return 0, err
+		}
+	default:
+		n = uint64(prefix)
+	}
+
+	if minItemSize > 0 && n > trustedPreallocateBound(minItemSize) {
+		return 0, errTooManyItems
+	}
+	return n, nil
+}
+
+func readFixed(r io.Reader, n int) ([]byte, error) {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// hashToBytes decodes a display-order (big-endian) hex hash into the
+// internal little-endian 32 bytes used on the wire.
+func hashToBytes(hash string) ([]byte, error) {
+	b, err := hex.DecodeString(hash)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) != 32 {
+		b = append(make([]byte, 32-len(b)), b...)
+	}
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return b, nil
+}
+
+func bytesToHash(b []byte) string {
+	rev := make([]byte, len(b))
+	for i, j := 0, len(b)-1; j >= 0; i, j = i+1, j-1 {
+		rev[i] = b[j]
+	}
+	return hex.EncodeToString(rev)
+}
+
+// hex4LE decodes a 4-byte hex field such as versiongroupid or
+// consensusbranchid into the uint32 the wire format carries little-endian.
+// Like the block/tx hashes that hashToBytes handles, zcashd reports these
+// in display (big-endian) order, e.g. "c2d6d0b4" for NU5.
+func hex4LE(hexStr string) (uint32, error) {
+	b, err := hex.DecodeString(hexStr)
+	if err != nil {
+		return 0, err
+	}
+	if len(b) != 4 {
+		return 0, fmt.Errorf("zTypes: expected a 4-byte hex field, got %d bytes", len(b))
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
+
+func hex4LEString(v uint32) string {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return hex.EncodeToString(b)
+}
+
+// zatoshi converts a ZEC amount (as reported by zcashd's JSON RPC) to
+// zatoshis for wire encoding. This is a lossy float64->int64 conversion;
+// Amount (see pool.go) is the fix for callers who need exact reconciliation.
+func zatoshi(zec float64) int64 {
+	return int64(math.Round(zec * 1e8))
+}
+
+func zecAmount(zat int64) float64 {
+	return float64(zat) / 1e8
+}
+
+// ZcashSerialize writes v in the consensus wire format: a 32-byte reversed
+// prevout hash, a 4-byte prevout index, a CompactSize-prefixed scriptSig,
+// and a 4-byte sequence number.
+func (v VInTX) ZcashSerialize(w io.Writer) error {
+	hashBytes, err := hashToBytes(v.TxID)
+	if err != nil {
+		return err
+	}
+	if err := writeBytes(w, hashBytes); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(v.VOut)); err != nil {
+		return err
+	}
+	// ScriptSig bytes aren't retained on this type today; encode as empty.
+	if err := writeCompactSize(w, 0); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, uint32(v.Sequence))
+}
+
+// ZcashDeserialize reads v back from the consensus wire format written by
+// ZcashSerialize.
+func (v *VInTX) ZcashDeserialize(r io.Reader) error {
+	hashBytes, err := readFixed(r, 32)
+	if err != nil {
+		return err
+	}
+	v.TxID = bytesToHash(hashBytes)
+
+	var vout, sequence uint32
+	if err := binary.Read(r, binary.LittleEndian, &vout); err != nil {
+		return err
+	}
+	v.VOut = int(vout)
+
+	scriptLen, err := readCompactSize(r, 1)
+	if err != nil {
+		return err
+	}
+	if _, err := readFixed(r, int(scriptLen)); err != nil {
+		return err
+	}
+
+	if err := binary.Read(r, binary.LittleEndian, &sequence); err != nil {
+		return err
+	}
+	v.Sequence = int(sequence)
+	return nil
+}
+
+// ZcashSerialize writes v as an 8-byte zatoshi amount followed by a
+// CompactSize-prefixed scriptPubKey.
+func (v VOutTX) ZcashSerialize(w io.Writer) error {
+	if err := binary.Write(w, binary.LittleEndian, zatoshi(v.Value)); err != nil {
+		return err
+	}
+	// scriptPubKey bytes aren't retained on this type today; encode as empty.
+	return writeCompactSize(w, 0)
+}
+
+// ZcashDeserialize reads v back from the consensus wire format written by
+// ZcashSerialize.
+func (v *VOutTX) ZcashDeserialize(r io.Reader) error {
+	var zat int64
+	if err := binary.Read(r, binary.LittleEndian, &zat); err != nil {
+		return err
+	}
+	v.Value = zecAmount(zat)
+
+	scriptLen, err := readCompactSize(r, 1)
+	if err != nil {
+		return err
+	}
+	_, err = readFixed(r, int(scriptLen))
+	return err
+}
+
+// ZcashSerialize writes v as two 8-byte zatoshi amounts, vpub_old then
+// vpub_new. The Sprout commitments/nullifiers/proof/MACs/ciphertexts
+// aren't modeled on VJoinSplitTX yet.
+func (v VJoinSplitTX) ZcashSerialize(w io.Writer) error {
+	if err := binary.Write(w, binary.LittleEndian, zatoshi(v.VPubOldld)); err != nil {
+		return err
+	}
+	return binary.Write(w, binary.LittleEndian, zatoshi(v.VPubNew))
+}
+
+// ZcashDeserialize reads v back from the consensus wire format written by
+// ZcashSerialize.
+func (v *VJoinSplitTX) ZcashDeserialize(r io.Reader) error {
+	var vpubOld, vpubNew int64
+	if err := binary.Read(r, binary.LittleEndian, &vpubOld); err != nil {
+		return err
+	}
+	if err := binary.Read(r, binary.LittleEndian, &vpubNew); err != nil {
+		return err
+	}
+	v.VPubOldld = zecAmount(vpubOld)
+	v.VPubNew = zecAmount(vpubNew)
+	return nil
+}
+
+// ZcashSerialize writes a as an Orchard action: cv, nullifier, rk, cmx,
+// ephemeralKey, encCiphertext, outCiphertext, and spendAuthSig, each
+// hex-decoded and written as a CompactSize-prefixed opaque byte string.
+func (a OrchardAction) ZcashSerialize(w io.Writer) error {
+	fields := []string{a.Cv, a.Nullifier, a.Rk, a.Cmx, a.EphemeralKey, a.EncCiphertext, a.OutCiphertext, a.SpendAuthSig}
+	for _, f := range fields {
+		b, err := hex.DecodeString(f)
+		if err != nil {
+			return err
+		}
+		if err := writeCompactSize(w, uint64(len(b))); err != nil {
+			return err
+		}
+		if err := writeBytes(w, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ZcashDeserialize reads a back from the wire format written by
+// ZcashSerialize.
+func (a *OrchardAction) ZcashDeserialize(r io.Reader) error {
+	fields := []*string{&a.Cv, &a.Nullifier, &a.Rk, &a.Cmx, &a.EphemeralKey, &a.EncCiphertext, &a.OutCiphertext, &a.SpendAuthSig}
+	for _, f := range fields {
+		n, err := readCompactSize(r, 1)
+		if err != nil {
+			return err
+		}
+		b, err := readFixed(r, int(n))
+		if err != nil {
+			return err
+		}
+		*f = hex.EncodeToString(b)
+	}
+	return nil
+}
+
+// ZcashSerialize writes the Orchard bundle: a CompactSize-prefixed vector
+// of actions (TrustedPreallocate-bounded on read), the 8-byte zatoshi
+// valueBalanceOrchard, and the flags/proof/bindingSig byte strings.
+func (o OrchardBundle) ZcashSerialize(w io.Writer) error {
+	if err := writeCompactSize(w, uint64(len(o.Actions))); err != nil {
+		return err
+	}
+	for _, a := range o.Actions {
+		if err := a.ZcashSerialize(w); err != nil {
+			return err
+		}
+	}
+	if err := binary.Write(w, binary.LittleEndian, zatoshi(o.ValueBalance)); err != nil {
+		return err
+	}
+	for _, f := range []string{o.Flags, o.Proof, o.BindingSig} {
+		b, err := hex.DecodeString(f)
+		if err != nil {
+			return err
+		}
+		if err := writeCompactSize(w, uint64(len(b))); err != nil {
+			return err
+		}
+		if err := writeBytes(w, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// minOrchardActionSize is the smallest an encoded OrchardAction can be
+// (every length-prefixed field empty); used as the TrustedPreallocate
+// bound when reading the action count.
+const minOrchardActionSize = 8
+
+// ZcashDeserialize reads o back from the wire format written by
+// ZcashSerialize.
+func (o *OrchardBundle) ZcashDeserialize(r io.Reader) error {
+	n, err := readCompactSize(r, minOrchardActionSize)
+	if err != nil {
+		return err
+	}
+	o.Actions = make([]OrchardAction, n)
+	for i := range o.Actions {
+		if err := o.Actions[i].ZcashDeserialize(r); err != nil {
+			return err
+		}
+	}
+
+	var zat int64
+	if err := binary.Read(r, binary.LittleEndian, &zat); err != nil {
+		return err
+	}
+	o.ValueBalance = zecAmount(zat)
+
+	for _, f := range []*string{&o.Flags, &o.Proof, &o.BindingSig} {
+		flen, err := readCompactSize(r, 1)
+		if err != nil {
+			return err
+		}
+		b, err := readFixed(r, int(flen))
+		if err != nil {
+			return err
+		}
+		*f = hex.EncodeToString(b)
+	}
+	return nil
+}
+
+func writeBytes(w io.Writer, b []byte) error {
+	_, err := w.Write(b)
+	return err
+}
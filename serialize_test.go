@@ -0,0 +1,151 @@
+package zTypes
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func mustHash(b byte) string {
+	return strings.Repeat(string([]byte{'0' + b}), 64)
+}
+
+func TestTransactionRoundTripV1(t *testing.T) {
+	tx := Transaction{
+		Version:  1,
+		Locktime: 42,
+		VIn:      []VInTX{{TxID: mustHash(1), VOut: 2, Sequence: 0xffffffff}},
+		VOut:     []VOutTX{{Value: 1.23456789}},
+	}
+
+	var buf bytes.Buffer
+	if err := tx.ZcashSerialize(&buf); err != nil {
+		t.Fatalf("ZcashSerialize: %v", err)
+	}
+
+	var got Transaction
+	if err := got.ZcashDeserialize(&buf); err != nil {
+		t.Fatalf("ZcashDeserialize: %v", err)
+	}
+
+	if got.Version != tx.Version || got.Locktime != tx.Locktime {
+		t.Fatalf("header mismatch: got %+v", got)
+	}
+	if len(got.VIn) != 1 || got.VIn[0].TxID != tx.VIn[0].TxID || got.VIn[0].VOut != tx.VIn[0].VOut {
+		t.Fatalf("vin mismatch: got %+v", got.VIn)
+	}
+	if len(got.VOut) != 1 || got.VOut[0].Value != tx.VOut[0].Value {
+		t.Fatalf("vout mismatch: got %+v", got.VOut)
+	}
+}
+
+func TestTransactionRoundTripV4Sapling(t *testing.T) {
+	tx := Transaction{
+		Version:         4,
+		FOverwintered:   true,
+		NVersionGroupId: "85202f89",
+		Locktime:        1,
+		ExpiryHeight:    100,
+		VJoinSplit:      []VJoinSplitTX{{VPubOldld: 1.5, VPubNew: 0}},
+		ValueBalance:    -0.25,
+	}
+
+	var buf bytes.Buffer
+	if err := tx.ZcashSerialize(&buf); err != nil {
+		t.Fatalf("ZcashSerialize: %v", err)
+	}
+
+	var got Transaction
+	if err := got.ZcashDeserialize(&buf); err != nil {
+		t.Fatalf("ZcashDeserialize: %v", err)
+	}
+
+	if got.NVersionGroupId != tx.NVersionGroupId {
+		t.Fatalf("version group id mismatch: got %q want %q", got.NVersionGroupId, tx.NVersionGroupId)
+	}
+	if got.ExpiryHeight != tx.ExpiryHeight {
+		t.Fatalf("expiry height mismatch: got %d want %d", got.ExpiryHeight, tx.ExpiryHeight)
+	}
+	if len(got.VJoinSplit) != 1 || got.VJoinSplit[0].VPubOldld != tx.VJoinSplit[0].VPubOldld {
+		t.Fatalf("joinsplit mismatch: got %+v", got.VJoinSplit)
+	}
+	if got.ValueBalance != tx.ValueBalance {
+		t.Fatalf("valueBalance mismatch: got %v want %v", got.ValueBalance, tx.ValueBalance)
+	}
+}
+
+func TestTransactionRoundTripV5Orchard(t *testing.T) {
+	tx := Transaction{
+		Version:            5,
+		FOverwintered:      true,
+		NVersionGroupId:    "0a27a726",
+		NConsensusBranchId: "b4d0d6c2",
+		Locktime:           10,
+		ExpiryHeight:       20,
+		VIn:                []VInTX{{TxID: mustHash(2), VOut: 0}},
+		VOut:               []VOutTX{{Value: 2}},
+		ValueBalance:       0,
+		Orchard: &OrchardBundle{
+			Actions:      []OrchardAction{{Cv: "aa", Nullifier: "bb", Rk: "cc"}},
+			ValueBalance: 0.1,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := tx.ZcashSerialize(&buf); err != nil {
+		t.Fatalf("ZcashSerialize: %v", err)
+	}
+
+	var got Transaction
+	if err := got.ZcashDeserialize(&buf); err != nil {
+		t.Fatalf("ZcashDeserialize: %v", err)
+	}
+
+	if got.NVersionGroupId != tx.NVersionGroupId || got.NConsensusBranchId != tx.NConsensusBranchId {
+		t.Fatalf("version group/branch id mismatch: got %+v", got)
+	}
+	if got.Orchard == nil || len(got.Orchard.Actions) != 1 || got.Orchard.Actions[0].Cv != "aa" {
+		t.Fatalf("orchard bundle mismatch: got %+v", got.Orchard)
+	}
+	if got.Orchard.ValueBalance != tx.Orchard.ValueBalance {
+		t.Fatalf("orchard value balance mismatch: got %v want %v", got.Orchard.ValueBalance, tx.Orchard.ValueBalance)
+	}
+	if len(got.VJoinSplit) != 0 {
+		t.Fatalf("v5 transaction must not round-trip a joinsplit vector, got %+v", got.VJoinSplit)
+	}
+}
+
+func TestBlockRoundTrip(t *testing.T) {
+	b := Block{
+		Version: 4,
+		TX: []Transaction{
+			{Version: 1, VIn: []VInTX{{TxID: mustHash(3)}}},
+			{Version: 1, VOut: []VOutTX{{Value: 5}}},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := b.ZcashSerialize(&buf); err != nil {
+		t.Fatalf("ZcashSerialize: %v", err)
+	}
+
+	var got Block
+	if err := got.ZcashDeserialize(&buf); err != nil {
+		t.Fatalf("ZcashDeserialize: %v", err)
+	}
+	if len(got.TX) != 2 {
+		t.Fatalf("expected 2 transactions, got %d", len(got.TX))
+	}
+}
+
+func TestReadCompactSizeRejectsImplausibleCount(t *testing.T) {
+	var buf bytes.Buffer
+	// 0xff prefix + a huge 8-byte count: far more items than could fit in
+	// maxBlockBytes at 1 byte/item.
+	if err := writeCompactSize(&buf, 1<<40); err != nil {
+		t.Fatalf("writeCompactSize: %v", err)
+	}
+	if _, err := readCompactSize(&buf, 1); err != errTooManyItems {
+		t.Fatalf("expected errTooManyItems, got %v", err)
+	}
+}
@@ -0,0 +1,344 @@
+package zTypes
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// minVInSize/minVOutSize/minJoinSplitSize are the smallest possible
+// encodings of each vector element; they're the TrustedPreallocate bounds
+// used when reading the corresponding CompactSize count.
+const (
+	minVInSize       = 32 + 4 + 1 + 4
+	minVOutSize      = 8 + 1
+	minJoinSplitSize = 8 + 8
+)
+
+// errUnsupportedSaplingBundle is returned when deserializing a transaction
+// whose Sapling spend/output vectors are non-empty: VShieldedSpend and
+// VShieldedOutput are still generic JSON blobs, so the binary codec can't
+// round-trip them yet (see ZcashSerialize).
+var errUnsupportedSaplingBundle = errors.New("zTypes: ZcashDeserialize does not yet support non-empty Sapling spend/output vectors")
+
+// ZcashSerialize encodes t close to the Zcash consensus transaction
+// format: the pre-NU5 (V1-V4) layout for Version < 5, and the ZIP-225
+// (NU5/V5) layout for Version >= 5. It is not a certified consensus
+// parser - Sapling spend/output bundles aren't modeled as typed structs,
+// so those vectors always round-trip as empty. The header and the
+// transparent section follow the wire field order and widths zcashd uses,
+// with scriptSig and scriptPubKey written empty. The Orchard section does
+// not: it uses the layout of OrchardBundle.ZcashSerialize (every action
+// field CompactSize-prefixed, spendAuthSig inside each action,
+// valueBalanceOrchard before the flags) and omits the Orchard anchor.
+func (t Transaction) ZcashSerialize(w io.Writer) error {
+	header := uint32(t.Version)
+	if t.FOverwintered {
+		header |= 1 << 31
+	}
+	if err := binary.Write(w, binary.LittleEndian, header); err != nil {
+		return err
+	}
+
+	if t.Version >= 5 {
+		return t.serializeV5(w)
+	}
+	return t.serializeLegacy(w)
+}
+
+// serializeLegacy writes the body of a pre-NU5 (V1-V4) transaction:
+// [nVersionGroupId] vin vout nLockTime [nExpiryHeight] [vJoinSplit...]
+// [Sapling valueBalance+spends+outputs]. No Sapling bindingSig is written.
+func (t Transaction) serializeLegacy(w io.Writer) error {
+	if t.FOverwintered {
+		vg, err := hex4LE(t.NVersionGroupId)
+		if err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, vg); err != nil {
+			return err
+		}
+	}
+
+	if err := writeVector(w, len(t.VIn), func(i int) error { return t.VIn[i].ZcashSerialize(w) }); err != nil {
+		return err
+	}
+	if err := writeVector(w, len(t.VOut), func(i int) error { return t.VOut[i].ZcashSerialize(w) }); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(t.Locktime)); err != nil {
+		return err
+	}
+	if t.FOverwintered {
+		if err := binary.Write(w, binary.LittleEndian, uint32(t.ExpiryHeight)); err != nil {
+			return err
+		}
+	}
+
+	if t.Version >= 2 {
+		if err := writeVector(w, len(t.VJoinSplit), func(i int) error { return t.VJoinSplit[i].ZcashSerialize(w) }); err != nil {
+			return err
+		}
+	}
+
+	if t.Version >= 4 {
+		return t.serializeSaplingBundle(w)
+	}
+	return nil
+}
+
+// serializeV5 writes the body of a NU5 (V5, ZIP-225) transaction:
+// nVersionGroupId nConsensusBranchId nLockTime nExpiryHeight, the
+// transparent bundle, the Sapling bundle, and the Orchard bundle. V5
+// transactions carry no Sprout JoinSplit data.
+func (t Transaction) serializeV5(w io.Writer) error {
+	vg, err := hex4LE(t.NVersionGroupId)
+	if err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, vg); err != nil {
+		return err
+	}
+	branchID, err := hex4LE(t.NConsensusBranchId)
+	if err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, branchID); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(t.Locktime)); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(t.ExpiryHeight)); err != nil {
+		return err
+	}
+
+	if err := writeVector(w, len(t.VIn), func(i int) error { return t.VIn[i].ZcashSerialize(w) }); err != nil {
+		return err
+	}
+	if err := writeVector(w, len(t.VOut), func(i int) error { return t.VOut[i].ZcashSerialize(w) }); err != nil {
+		return err
+	}
+
+	if err := t.serializeSaplingBundle(w); err != nil {
+		return err
+	}
+
+	orchard := t.Orchard
+	if orchard == nil {
+		orchard = &OrchardBundle{}
+	}
+	return orchard.ZcashSerialize(w)
+}
+
+// serializeSaplingBundle writes valueBalance followed by empty spend and
+// output vectors - see the doc comment on Transaction.ZcashSerialize for why
+// the vectors are always empty.
+func (t Transaction) serializeSaplingBundle(w io.Writer) error {
+	if err := binary.Write(w, binary.LittleEndian, zatoshi(t.ValueBalance)); err != nil {
+		return err
+	}
+	if err := writeCompactSize(w, 0); err != nil {
+		return err
+	}
+	return writeCompactSize(w, 0)
+}
+
+func writeVector(w io.Writer, n int, writeItem func(i int) error) error {
+	if err := writeCompactSize(w, uint64(n)); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		if err := writeItem(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ZcashDeserialize reads t back from the wire format written by
+// ZcashSerialize.
+func (t *Transaction) ZcashDeserialize(r io.Reader) error {
+	var header uint32
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+	t.FOverwintered = header&(1<<31) != 0
+	t.Version = int(header &^ (1 << 31))
+
+	if t.Version >= 5 {
+		return t.deserializeV5(r)
+	}
+	return t.deserializeLegacy(r)
+}
+
+func (t *Transaction) deserializeLegacy(r io.Reader) error {
+	if t.FOverwintered {
+		var vg uint32
+		if err := binary.Read(r, binary.LittleEndian, &vg); err != nil {
+			return err
+		}
+		t.NVersionGroupId = hex4LEString(vg)
+	}
+
+	vinCount, err := readCompactSize(r, minVInSize)
+	if err != nil {
+		return err
+	}
+	t.VIn = make([]VInTX, vinCount)
+	for i := range t.VIn {
+		if err := t.VIn[i].ZcashDeserialize(r); err != nil {
+			return err
+		}
+	}
+
+	voutCount, err := readCompactSize(r, minVOutSize)
+	if err != nil {
+		return err
+	}
+	t.VOut = make([]VOutTX, voutCount)
+	for i := range t.VOut {
+		if err := t.VOut[i].ZcashDeserialize(r); err != nil {
+			return err
+		}
+	}
+
+	var locktime uint32
+	if err := binary.Read(r, binary.LittleEndian, &locktime); err != nil {
+		return err
+	}
+	t.Locktime = int(locktime)
+
+	if t.FOverwintered {
+		var expiry uint32
+		if err := binary.Read(r, binary.LittleEndian, &expiry); err != nil {
+			return err
+		}
+		t.ExpiryHeight = int(expiry)
+	}
+
+	if t.Version >= 2 {
+		jsCount, err := readCompactSize(r, minJoinSplitSize)
+		if err != nil {
+			return err
+		}
+		t.VJoinSplit = make([]VJoinSplitTX, jsCount)
+		for i := range t.VJoinSplit {
+			if err := t.VJoinSplit[i].ZcashDeserialize(r); err != nil {
+				return err
+			}
+		}
+	}
+
+	if t.Version >= 4 {
+		return t.deserializeSaplingBundle(r)
+	}
+	return nil
+}
+
+func (t *Transaction) deserializeV5(r io.Reader) error {
+	var vg, branchID uint32
+	if err := binary.Read(r, binary.LittleEndian, &vg); err != nil {
+		return err
+	}
+	t.NVersionGroupId = hex4LEString(vg)
+	if err := binary.Read(r, binary.LittleEndian, &branchID); err != nil {
+		return err
+	}
+	t.NConsensusBranchId = hex4LEString(branchID)
+
+	var locktime, expiry uint32
+	if err := binary.Read(r, binary.LittleEndian, &locktime); err != nil {
+		return err
+	}
+	t.Locktime = int(locktime)
+	if err := binary.Read(r, binary.LittleEndian, &expiry); err != nil {
+		return err
+	}
+	t.ExpiryHeight = int(expiry)
+
+	vinCount, err := readCompactSize(r, minVInSize)
+	if err != nil {
+		return err
+	}
+	t.VIn = make([]VInTX, vinCount)
+	for i := range t.VIn {
+		if err := t.VIn[i].ZcashDeserialize(r); err != nil {
+			return err
+		}
+	}
+
+	voutCount, err := readCompactSize(r, minVOutSize)
+	if err != nil {
+		return err
+	}
+	t.VOut = make([]VOutTX, voutCount)
+	for i := range t.VOut {
+		if err := t.VOut[i].ZcashDeserialize(r); err != nil {
+			return err
+		}
+	}
+
+	if err := t.deserializeSaplingBundle(r); err != nil {
+		return err
+	}
+
+	t.Orchard = &OrchardBundle{}
+	return t.Orchard.ZcashDeserialize(r)
+}
+
+func (t *Transaction) deserializeSaplingBundle(r io.Reader) error {
+	var vb int64
+	if err := binary.Read(r, binary.LittleEndian, &vb); err != nil {
+		return err
+	}
+	t.ValueBalance = zecAmount(vb)
+
+	for i := 0; i < 2; i++ {
+		n, err := readCompactSize(r, 1)
+		if err != nil {
+			return err
+		}
+		if n != 0 {
+			return errUnsupportedSaplingBundle
+		}
+	}
+	return nil
+}
+
+// minTxSize is the smallest possible encoded transaction (a bare V1
+// transparent transaction with no inputs or outputs); used as the
+// TrustedPreallocate bound when reading a block's tx count.
+const minTxSize = 4 + 1 + 1 + 4
+
+// ZcashSerialize writes b as a 4-byte version, followed by the
+// CompactSize-prefixed vector of transactions. The block header fields
+// (merkleroot, finalsaplingroot, nonce, ...) aren't modeled on Block yet.
+func (b Block) ZcashSerialize(w io.Writer) error {
+	if err := binary.Write(w, binary.LittleEndian, uint32(b.Version)); err != nil {
+		return err
+	}
+	return writeVector(w, len(b.TX), func(i int) error { return b.TX[i].ZcashSerialize(w) })
+}
+
+// ZcashDeserialize reads b back from the wire format written by
+// ZcashSerialize.
+func (b *Block) ZcashDeserialize(r io.Reader) error {
+	var version uint32
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return err
+	}
+	b.Version = int(version)
+
+	txCount, err := readCompactSize(r, minTxSize)
+	if err != nil {
+		return err
+	}
+	b.TX = make([]Transaction, txCount)
+	for i := range b.TX {
+		if err := b.TX[i].ZcashDeserialize(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
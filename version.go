@@ -0,0 +1,92 @@
+package zTypes
+
+import "encoding/json"
+
+// Consensus branch IDs, as reported in the `consensusbranchid` field of a
+// verbose `getrawtransaction`/`getblock` response. These identify the
+// network upgrade active when the transaction was mined more precisely
+// than version/versiongroupid alone, since Sapling through Canopy all
+// share the same version (4) and version group ID.
+const (
+	branchIDOverwinter = "5ba81b19"
+	branchIDSapling    = "76b809bb"
+	branchIDBlossom    = "2bb40e60"
+	branchIDHeartwood  = "f5b9230b"
+	branchIDCanopy     = "e9ff75a6"
+	branchIDNU5        = "c2d6d0b4"
+)
+
+// DecodeTransaction unmarshals a raw getrawtransaction payload into a
+// Transaction, then dispatches on the decoded version to clear whichever
+// version-specific fields don't apply to it - e.g. a V4 transaction has
+// no Orchard bundle and no shared Sapling anchor, a V5 transaction has no
+// Sprout JoinSplits - so callers never see stale or out-of-place data
+// left over from a malformed or hand-built payload.
+func DecodeTransaction(raw json.RawMessage) (Transaction, error) {
+	var t Transaction
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return Transaction{}, err
+	}
+
+	switch {
+	case t.Version < 2:
+		// V1: transparent only.
+		t.VJoinSplit = nil
+		t.VShieldedSpend = nil
+		t.VShieldedOutput = nil
+		t.ValueBalance = 0
+		t.AnchorSapling = ""
+		t.Orchard = nil
+	case t.Version < 4:
+		// V2/V3 (Overwinter): Sprout JoinSplits only.
+		t.VShieldedSpend = nil
+		t.VShieldedOutput = nil
+		t.ValueBalance = 0
+		t.AnchorSapling = ""
+		t.Orchard = nil
+	case t.Version == 4:
+		// V4 (Sapling): each VShieldedSpend carries its own anchor; the
+		// single shared anchor is V5-only.
+		t.AnchorSapling = ""
+		t.Orchard = nil
+	default:
+		// V5 (NU5): Sprout was removed, and Sapling spends share a single
+		// anchor (AnchorSapling) rather than one anchor per spend.
+		t.VJoinSplit = nil
+	}
+
+	return t, nil
+}
+
+// NetworkUpgrade returns the name of the network upgrade under whose
+// consensus rules this transaction was built: one of Overwinter, Sapling,
+// Blossom, Heartwood, Canopy, or NU5. It returns an empty string for pre-
+// Overwinter (V1/V2) transactions, which aren't tied to a branch ID.
+func (t Transaction) NetworkUpgrade() string {
+	switch t.NConsensusBranchId {
+	case branchIDOverwinter:
+		return "Overwinter"
+	case branchIDSapling:
+		return "Sapling"
+	case branchIDBlossom:
+		return "Blossom"
+	case branchIDHeartwood:
+		return "Heartwood"
+	case branchIDCanopy:
+		return "Canopy"
+	case branchIDNU5:
+		return "NU5"
+	}
+
+	// Fall back to a coarser guess from version/versiongroupid alone.
+	switch {
+	case t.Version >= 5:
+		return "NU5"
+	case t.Version == 4 && t.FOverwintered:
+		return "Sapling"
+	case t.Version == 3 && t.FOverwintered:
+		return "Overwinter"
+	default:
+		return ""
+	}
+}
@@ -10,11 +10,13 @@ type BlockMetric struct {
 	NumberofTransactions int     `json:"number_of_transactions"`
 	SaplingValuePool     float64 `json:"sapling_value_pool"`
 	SproutValuePool      float64 `json:"sprout_value_pool"`
+	OrchardValuePool     float64 `json:"orchard_value_pool"`
 	Size                 int     `json:"size"`
 	Time                 int64   `json:"time"`
 	NumberofTransparent  int     `json:"number_of_transparent_transactions"`
 	NumberofShielded     int     `json:"number_of_shielded_transactions"`
 	NumberofMixed        int     `json:"number_of_mixed_transactions"`
+	NumberofOrchard      int     `json:"number_of_orchard_transactions"`
 }
 
 // GetBlockchainInfo return the zcashd rpc `getblockchaininfo` status
@@ -54,14 +56,12 @@ type Block struct {
 
 func (b Block) TransactionTypes() (tTXs, sTXs int) {
 	for _, tx := range b.TX {
-		// If all 3 fields are empty, the transaction is transparent
-		if len(tx.VJoinSplit) > 0 ||
-			len(tx.VShieldedOutput) > 0 ||
-			len(tx.VShieldedSpend) > 0 {
-			tTXs++
-		} else {
-			// Otherwise, it's a shielded transaction
+		// If any of the shielded pools has data, the transaction is shielded
+		if tx.ContainsSprout() || tx.ContainsSapling() || tx.ContainsOrchard() {
 			sTXs++
+		} else {
+			// Otherwise, it's a transparent transaction
+			tTXs++
 		}
 	}
 	return tTXs, sTXs
@@ -93,6 +93,15 @@ func (b Block) SproutValuePool() float64 {
 	return 0
 }
 
+func (b Block) OrchardValuePool() float64 {
+	for _, pool := range b.ValuePools {
+		if pool.ID == "orchard" {
+			return pool.ChainValue
+		}
+	}
+	return 0
+}
+
 func (b Block) NumberofTransactions() int {
 	return len(b.TX)
 }
@@ -111,13 +120,46 @@ type Transaction struct {
 	Txid            string                   `json:"txid"`
 	Version         int                      `json:"version"`
 	Locktime        int                      `json:"locktime"`
-	ExpiryHeight    int                      `json:"expirtheight"`
+	ExpiryHeight    int                      `json:"expiryheight"`
 	VIn             []VInTX                  `json:"vin"`
 	VOut            []VOutTX                 `json:"vout"`
 	VJoinSplit      []VJoinSplitTX           `json:"vjoinsplit"`
 	ValueBalance    float64                  `json:"valueBalance"`
 	VShieldedSpend  []map[string]interface{} `json:"vShieldedSpend"`
 	VShieldedOutput []map[string]interface{} `json:"vShieldedOutput"`
+	Orchard         *OrchardBundle           `json:"orchard,omitempty"`
+
+	// Overwinter+ fields. FOverwintered/NVersionGroupId are present from
+	// V3 onward; NConsensusBranchId and AnchorSapling (the single anchor
+	// shared by every Sapling spend) only appear from V5 onward.
+	FOverwintered      bool   `json:"overwintered,omitempty"`
+	NVersionGroupId    string `json:"versiongroupid,omitempty"`
+	NConsensusBranchId string `json:"consensusbranchid,omitempty"`
+	AnchorSapling      string `json:"anchorSapling,omitempty"`
+}
+
+// OrchardBundle carries the v5/NU5 Orchard shielded bundle of a transaction,
+// as returned under the `orchard` key of zcashd's `getrawtransaction`.
+type OrchardBundle struct {
+	Actions      []OrchardAction `json:"actions"`
+	ValueBalance float64         `json:"valueBalanceOrchard"`
+	Anchor       string          `json:"anchorOrchard"`
+	Flags        string          `json:"flagsOrchard"`
+	Proof        string          `json:"proofsOrchard"`
+	BindingSig   string          `json:"bindingSigOrchard"`
+}
+
+// OrchardAction is a single Orchard action (one spend and one output,
+// bundled together under the Orchard circuit).
+type OrchardAction struct {
+	Cv            string `json:"cv"`
+	Nullifier     string `json:"nullifier"`
+	Rk            string `json:"rk"`
+	Cmx           string `json:"cmx"`
+	EphemeralKey  string `json:"ephemeralKey"`
+	EncCiphertext string `json:"encCiphertext"`
+	OutCiphertext string `json:"outCiphertext"`
+	SpendAuthSig  string `json:"spendAuthSig"`
 }
 
 // TransparentInAndOut return if there are transparent
@@ -133,7 +175,8 @@ func (t Transaction) IsTransparent() bool {
 		len(t.VJoinSplit) == 0 &&
 		t.ValueBalance == 0 &&
 		len(t.VShieldedSpend) == 0 &&
-		len(t.VShieldedSpend) == 0
+		len(t.VShieldedOutput) == 0 &&
+		!t.ContainsOrchard()
 }
 
 // ContainsSprout returns if a transaction contains
@@ -144,18 +187,27 @@ func (t Transaction) ContainsSprout() bool {
 
 // ContainsSapling returns if a transaction contains
 // sapling transaction data
-// Check that there is a valueBalance value (positive or negative)
-// Check that there is data for either VShieldedSpend or VShieldedOutput
+// A fully-shielded Sapling tx with no transparent flow and no fee has
+// valueBalance == 0, so presence is determined by the spend/output
+// vectors; a non-zero valueBalance alone (e.g. a parse of a bare value
+// with no vectors) also counts.
 func (t Transaction) ContainsSapling() bool {
-	return t.ValueBalance != 0 && (len(t.VShieldedSpend) > 0 ||
-		len(t.VShieldedOutput) > 0)
+	return t.ValueBalance != 0 ||
+		len(t.VShieldedSpend) > 0 ||
+		len(t.VShieldedOutput) > 0
+}
+
+// ContainsOrchard returns if a transaction contains
+// orchard transaction data
+func (t Transaction) ContainsOrchard() bool {
+	return t.Orchard != nil && len(t.Orchard.Actions) > 0
 }
 
 // IsShielded returns if the transaction contains
 // no transparent addresses
 func (t Transaction) IsShielded() bool {
 	return !t.TransparentInAndOut() &&
-		(t.ContainsSprout() || t.ContainsSapling())
+		(t.ContainsSprout() || t.ContainsSapling() || t.ContainsOrchard())
 }
 
 // IsMixed returns if the transaction contains
@@ -163,7 +215,7 @@ func (t Transaction) IsShielded() bool {
 func (t Transaction) IsMixed() bool {
 	tInOrOut := len(t.VIn) > 0 || len(t.VOut) > 0
 	return tInOrOut &&
-		(t.ContainsSprout() || t.ContainsSapling())
+		(t.ContainsSprout() || t.ContainsSapling() || t.ContainsOrchard())
 }
 
 type VInTX struct {